@@ -0,0 +1,339 @@
+// Package geojson_http implements the tegola provider.Tiler and
+// provider.Filterer interfaces against a REST endpoint that returns
+// paginated GeoJSON FeatureCollections, such as an OGC API - Features
+// server.
+package geojson_http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-spatial/geom"
+	"github.com/go-spatial/geom/encoding/geojson"
+	"github.com/go-spatial/tegola/provider"
+)
+
+// Config keys for the geojson_http provider.
+const (
+	ConfigKeyLayers    = "layers"
+	ConfigKeyLayerName = "name"
+	ConfigKeyURL       = "url"
+	ConfigKeySRID      = "srid"
+)
+
+// Name is the name the provider is registered under.
+const Name = "geojson_http"
+
+// DefaultSRID is the SRID tegola's pipeline works in.
+const DefaultSRID = 3857
+
+func init() {
+	provider.Register(Name, NewTileProvider, nil)
+}
+
+// Layer is a single named layer backed by a REST endpoint.
+type Layer struct {
+	name string
+	url  string
+	srid uint64
+}
+
+func (l Layer) Name() string             { return l.name }
+func (l Layer) GeomType() geom.Geometry  { return nil }
+func (l Layer) SRID() uint64             { return l.srid }
+func (l Layer) ModificationTag() *string { return nil }
+
+// Provider is a Tiler (and Filterer) backed by one or more REST endpoints
+// returning paginated GeoJSON.
+type Provider struct {
+	client *http.Client
+	layers map[string]Layer
+}
+
+var (
+	_ provider.Tiler    = Provider{}
+	_ provider.Filterer = Provider{}
+)
+
+// NewTileProvider instantiates and returns a new geojson_http provider.
+func NewTileProvider(config map[string]interface{}) (provider.Tiler, error) {
+	srid := uint64(DefaultSRID)
+	switch v := config[ConfigKeySRID].(type) {
+	case uint64:
+		srid = v
+	case int64:
+		srid = uint64(v)
+	case int:
+		srid = uint64(v)
+	case float64:
+		srid = uint64(v)
+	}
+
+	p := Provider{
+		client: http.DefaultClient,
+		layers: map[string]Layer{},
+	}
+
+	layerConfigs, _ := config[ConfigKeyLayers].([]map[string]interface{})
+	for _, lc := range layerConfigs {
+		name, ok := lc[ConfigKeyLayerName].(string)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("geojson_http: layer missing %v", ConfigKeyLayerName)
+		}
+		u, ok := lc[ConfigKeyURL].(string)
+		if !ok || u == "" {
+			return nil, fmt.Errorf("geojson_http: layer %v missing %v", name, ConfigKeyURL)
+		}
+
+		p.layers[name] = Layer{name: name, url: u, srid: srid}
+	}
+
+	return p, nil
+}
+
+// Layers returns the layers configured on this provider.
+func (p Provider) Layers() ([]provider.LayerInfo, error) {
+	infos := make([]provider.LayerInfo, 0, len(p.layers))
+	for _, l := range p.layers {
+		infos = append(infos, l)
+	}
+	return infos, nil
+}
+
+// TileFeatures implements provider.Tiler by substituting !BBOX! into the
+// layer's configured URL and paging through the resulting
+// FeatureCollection.
+func (p Provider) TileFeatures(ctx context.Context, layer string, t provider.Tile, fn func(f *provider.Feature) error) error {
+	l, ok := p.layers[layer]
+	if !ok {
+		return fmt.Errorf("geojson_http: layer %v not registered", layer)
+	}
+
+	extent, srid := t.BufferedExtent()
+	u := strings.Replace(l.url, "!BBOX!", bboxParam(extent), -1)
+
+	return p.streamPages(ctx, u, srid, fn)
+}
+
+// StreamFeatures implements provider.Filterer by mapping bounds and
+// properties onto query parameters, rather than substituting tokens into
+// the URL.
+func (p Provider) StreamFeatures(ctx context.Context, layer string, bounds provider.Bounder, properties map[string]string, fn provider.FeatureConsumer) error {
+	l, ok := p.layers[layer]
+	if !ok {
+		return fmt.Errorf("geojson_http: layer %v not registered", layer)
+	}
+
+	u, err := addFilterParams(l.url, bounds, properties)
+	if err != nil {
+		return err
+	}
+
+	return p.streamPages(ctx, u, l.srid, fn)
+}
+
+// streamPages walks the "next" link relation or cursor token, decoding
+// each FeatureCollection's features array with a streaming json.Decoder
+// so memory stays bounded regardless of collection size, until there's no
+// next page or fn/ctx ends the stream.
+func (p Provider) streamPages(ctx context.Context, u string, srid uint64, fn provider.FeatureConsumer) error {
+	for u != "" {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		next, err := p.fetchPage(ctx, u, srid, fn)
+		if err != nil {
+			return err
+		}
+		if next == "" {
+			return nil
+		}
+
+		u, err = nextPageURL(u, next)
+		if err != nil {
+			return fmt.Errorf("geojson_http: unable to resolve next page: %w", err)
+		}
+	}
+	return nil
+}
+
+// nextPageURL turns a "next" page reference from the current page (either
+// an absolute link-relation href or an opaque cursor token) into the URL
+// to request next. An absolute href is followed directly; anything else
+// is treated as an opaque cursor and set as a ?cursor= parameter on the
+// current page's URL, preserving its other query parameters (bbox, time,
+// property filters, ...).
+func nextPageURL(current, next string) (string, error) {
+	base, err := url.Parse(current)
+	if err != nil {
+		return "", fmt.Errorf("invalid current page url %v: %w", current, err)
+	}
+
+	if ref, err := url.Parse(next); err == nil && ref.IsAbs() {
+		return base.ResolveReference(ref).String(), nil
+	}
+
+	q := base.Query()
+	q.Set("cursor", next)
+	base.RawQuery = q.Encode()
+	return base.String(), nil
+}
+
+// fetchPage issues a single GET, decodes its FeatureCollection, and
+// returns the "next" page URL, if any.
+func (p Provider) fetchPage(ctx context.Context, u string, srid uint64, fn provider.FeatureConsumer) (next string, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return "", fmt.Errorf("geojson_http: unable to build request: %w", err)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("geojson_http: request to %v failed: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("geojson_http: request to %v returned status %v", u, resp.StatusCode)
+	}
+
+	return streamFeatureCollection(ctx, json.NewDecoder(resp.Body), srid, fn)
+}
+
+// streamFeatureCollection walks a FeatureCollection object token-by-token,
+// decoding the "features" array one element at a time so memory stays
+// bounded by a single feature regardless of how large the collection (or
+// a single unpaginated response) is, rather than buffering the whole
+// array before processing it. The "links"/"next" page-cursor fields are
+// small and decoded normally.
+func streamFeatureCollection(ctx context.Context, dec *json.Decoder, srid uint64, fn provider.FeatureConsumer) (next string, err error) {
+	if _, err := dec.Token(); err != nil { // consume '{'
+		return "", fmt.Errorf("geojson_http: unable to decode response: %w", err)
+	}
+
+	for dec.More() {
+		keyTok, err := dec.Token()
+		if err != nil {
+			return "", fmt.Errorf("geojson_http: unable to decode response: %w", err)
+		}
+		key, _ := keyTok.(string)
+
+		switch key {
+		case "features":
+			if err := streamFeatures(ctx, dec, srid, fn); err != nil {
+				return "", err
+			}
+		case "next":
+			if err := dec.Decode(&next); err != nil {
+				return "", fmt.Errorf("geojson_http: unable to decode next cursor: %w", err)
+			}
+		case "links":
+			var links []struct {
+				Rel  string `json:"rel"`
+				Href string `json:"href"`
+			}
+			if err := dec.Decode(&links); err != nil {
+				return "", fmt.Errorf("geojson_http: unable to decode links: %w", err)
+			}
+			for _, link := range links {
+				if link.Rel == "next" {
+					next = link.Href
+				}
+			}
+		default:
+			var discard interface{}
+			if err := dec.Decode(&discard); err != nil {
+				return "", fmt.Errorf("geojson_http: unable to decode field %v: %w", key, err)
+			}
+		}
+	}
+
+	return next, nil
+}
+
+// streamFeatures consumes the "features" array's '[' token, decodes each
+// element directly into a geojson.Feature and invokes fn for it, then
+// consumes the closing ']' token. No more than one feature is ever held
+// in memory at a time.
+func streamFeatures(ctx context.Context, dec *json.Decoder, srid uint64, fn provider.FeatureConsumer) error {
+	if _, err := dec.Token(); err != nil { // consume '['
+		return fmt.Errorf("geojson_http: unable to decode features array: %w", err)
+	}
+
+	for dec.More() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var gf geojson.Feature
+		if err := dec.Decode(&gf); err != nil {
+			return fmt.Errorf("geojson_http: unable to decode feature: %w", err)
+		}
+
+		f := &provider.Feature{
+			ID:       gf.ID,
+			Geometry: gf.Geometry.Geometry,
+			SRID:     srid,
+			Tags:     gf.Properties,
+		}
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+
+	if _, err := dec.Token(); err != nil { // consume ']'
+		return fmt.Errorf("geojson_http: unable to decode features array: %w", err)
+	}
+	return nil
+}
+
+func bboxParam(extent *geom.Extent) string {
+	return fmt.Sprintf("%v,%v,%v,%v", extent.MinX(), extent.MinY(), extent.MaxX(), extent.MaxY())
+}
+
+// addFilterParams maps a Bounder and property filters onto query
+// parameters: time=, bbox=, offset=/limit= (from the index extent's start
+// and its end minus start), and arbitrary &key=value pairs for properties.
+func addFilterParams(rawURL string, bounds provider.Bounder, properties map[string]string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("geojson_http: invalid url %v: %w", rawURL, err)
+	}
+
+	q := u.Query()
+
+	if bounds != nil {
+		if te := bounds.TimeExtent(); te != nil {
+			start, end := (*te).StartTime(), (*te).EndTime()
+			switch {
+			case start != nil && end != nil:
+				q.Set("time", start.UTC().Format(time.RFC3339)+"/"+end.UTC().Format(time.RFC3339))
+			case start != nil:
+				q.Set("time", start.UTC().Format(time.RFC3339)+"/..")
+			case end != nil:
+				q.Set("time", "../"+end.UTC().Format(time.RFC3339))
+			}
+		}
+		if ge := bounds.GeomExtent(); ge != nil {
+			q.Set("bbox", bboxParam(ge))
+		}
+		if ie := bounds.IndexExtent(); ie != nil {
+			q.Set("offset", strconv.FormatUint(uint64((*ie).StartIndex()), 10))
+			q.Set("limit", strconv.FormatUint(uint64((*ie).EndIndex()-(*ie).StartIndex()), 10))
+		}
+	}
+
+	for k, v := range properties {
+		q.Set(k, v)
+	}
+
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
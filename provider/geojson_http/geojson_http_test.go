@@ -0,0 +1,121 @@
+package geojson_http
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-spatial/geom"
+	"github.com/go-spatial/tegola/provider"
+)
+
+func TestBBoxParam(t *testing.T) {
+	extent := &geom.Extent{-1, -2, 3, 4}
+	got := bboxParam(extent)
+	want := "-1,-2,3,4"
+	if got != want {
+		t.Errorf("bboxParam() = %v, want %v", got, want)
+	}
+}
+
+func TestAddFilterParamsProperties(t *testing.T) {
+	u, err := addFilterParams("http://example.com/features", nil, map[string]string{"height": "9"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(u, "height=9") {
+		t.Errorf("expected url to contain height=9, got %v", u)
+	}
+}
+
+func TestAddFilterParamsInvalidURL(t *testing.T) {
+	if _, err := addFilterParams(":not a url", nil, nil); err == nil {
+		t.Error("expected error for invalid url, got nil")
+	}
+}
+
+func TestStreamFeatureCollection(t *testing.T) {
+	body := `{
+		"type": "FeatureCollection",
+		"features": [
+			{"type": "Feature", "id": 1, "properties": {"name": "a"}},
+			{"type": "Feature", "id": 2, "properties": {"name": "b"}}
+		],
+		"links": [
+			{"rel": "self", "href": "http://example.com/page1"},
+			{"rel": "next", "href": "http://example.com/page2"}
+		]
+	}`
+
+	var got []uint64
+	next, err := streamFeatureCollection(context.Background(), json.NewDecoder(strings.NewReader(body)), 3857, func(f *provider.Feature) error {
+		got = append(got, f.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if next != "http://example.com/page2" {
+		t.Errorf("next = %v, want http://example.com/page2", next)
+	}
+	if len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Errorf("unexpected features decoded: %v", got)
+	}
+}
+
+func TestStreamFeatureCollectionNextCursor(t *testing.T) {
+	body := `{"features": [], "next": "cursor-123"}`
+
+	next, err := streamFeatureCollection(context.Background(), json.NewDecoder(strings.NewReader(body)), 3857, func(f *provider.Feature) error {
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if next != "cursor-123" {
+		t.Errorf("next = %v, want cursor-123", next)
+	}
+}
+
+func TestStreamFeatureCollectionNoNext(t *testing.T) {
+	body := `{"features": []}`
+
+	next, err := streamFeatureCollection(context.Background(), json.NewDecoder(strings.NewReader(body)), 3857, func(f *provider.Feature) error {
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if next != "" {
+		t.Errorf("next = %v, want empty string", next)
+	}
+}
+
+func TestNextPageURLAbsoluteHref(t *testing.T) {
+	got, err := nextPageURL("http://example.com/features?bbox=1,2,3,4", "http://example.com/features?page=2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "http://example.com/features?page=2" {
+		t.Errorf("nextPageURL() = %v, want http://example.com/features?page=2", got)
+	}
+}
+
+func TestNextPageURLCursorToken(t *testing.T) {
+	got, err := nextPageURL("http://example.com/features?bbox=1,2,3,4", "cursor-123")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	u, err := url.Parse(got)
+	if err != nil {
+		t.Fatalf("nextPageURL() returned unparseable url %v: %v", got, err)
+	}
+	if u.Query().Get("cursor") != "cursor-123" {
+		t.Errorf("nextPageURL() = %v, want cursor=cursor-123 param", got)
+	}
+	if u.Query().Get("bbox") != "1,2,3,4" {
+		t.Errorf("nextPageURL() = %v, want bbox param preserved", got)
+	}
+}
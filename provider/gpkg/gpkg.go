@@ -0,0 +1,328 @@
+// Package gpkg implements the tegola provider.Tiler interface against a
+// single-file GeoPackage (SQLite + SpatiaLite) database, as a portable
+// peer of provider/postgis for small-to-medium deployments that don't
+// want a Postgres dependency.
+package gpkg
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	_ "github.com/shaxbee/go-spatialite"
+
+	"github.com/go-spatial/geom"
+	"github.com/go-spatial/tegola/provider"
+)
+
+// Config keys for the gpkg provider.
+const (
+	ConfigKeyFilePath  = "filepath"
+	ConfigKeyLayers    = "layers"
+	ConfigKeyLayerName = "name"
+	ConfigKeySQL       = "sql"
+	ConfigKeyGeomField = "geometry_fieldname"
+	ConfigKeyIDField   = "id_fieldname"
+	ConfigKeyTableName = "tablename"
+	ConfigKeySRID      = "srid"
+)
+
+// Name is the name the provider is registered under.
+const Name = "gpkg"
+
+// DefaultSRID is the SRID tegola's pipeline works in.
+const DefaultSRID = 3857
+
+func init() {
+	provider.Register(Name, NewTileProvider, nil)
+}
+
+// Layer holds the configuration and cached metadata for a single
+// configured layer.
+type Layer struct {
+	name          string
+	sql           string
+	geomFieldname string
+	idFieldname   string
+	geomType      geom.Geometry
+	srid          uint64
+	tag           *string
+}
+
+func (l Layer) Name() string             { return l.name }
+func (l Layer) GeomType() geom.Geometry  { return l.geomType }
+func (l Layer) SRID() uint64             { return l.srid }
+func (l Layer) ModificationTag() *string { return l.tag }
+
+// Provider is a Tiler backed by a GeoPackage file.
+type Provider struct {
+	db     *sql.DB
+	srid   uint64
+	layers map[string]Layer
+}
+
+// NewTileProvider instantiates and returns a new gpkg provider, opening
+// the backing GeoPackage file and inspecting each configured layer's
+// geometry type and last-change timestamp.
+func NewTileProvider(config map[string]interface{}) (provider.Tiler, error) {
+	fp, ok := config[ConfigKeyFilePath].(string)
+	if !ok || fp == "" {
+		return nil, fmt.Errorf("gpkg: %v is required and must be a string", ConfigKeyFilePath)
+	}
+
+	db, err := sql.Open("spatialite", fp)
+	if err != nil {
+		return nil, fmt.Errorf("gpkg: unable to open %v: %w", fp, err)
+	}
+
+	srid := uint64(DefaultSRID)
+	switch v := config[ConfigKeySRID].(type) {
+	case uint64:
+		srid = v
+	case int64:
+		srid = uint64(v)
+	case int:
+		srid = uint64(v)
+	case float64:
+		srid = uint64(v)
+	}
+
+	p := Provider{
+		db:     db,
+		srid:   srid,
+		layers: map[string]Layer{},
+	}
+
+	layerConfigs, _ := config[ConfigKeyLayers].([]map[string]interface{})
+	for _, lc := range layerConfigs {
+		name, ok := lc[ConfigKeyLayerName].(string)
+		if !ok || name == "" {
+			db.Close()
+			return nil, fmt.Errorf("gpkg: layer missing %v", ConfigKeyLayerName)
+		}
+		sqlStr, ok := lc[ConfigKeySQL].(string)
+		if !ok || sqlStr == "" {
+			db.Close()
+			return nil, fmt.Errorf("gpkg: layer %v missing %v", name, ConfigKeySQL)
+		}
+
+		geomFieldname, _ := lc[ConfigKeyGeomField].(string)
+		if geomFieldname == "" {
+			geomFieldname = "geom"
+		}
+		idFieldname, _ := lc[ConfigKeyIDField].(string)
+		if idFieldname == "" {
+			idFieldname = "fid"
+		}
+		tableName, _ := lc[ConfigKeyTableName].(string)
+
+		layer := Layer{
+			name:          name,
+			sql:           sqlStr,
+			geomFieldname: geomFieldname,
+			idFieldname:   idFieldname,
+			srid:          p.srid,
+		}
+
+		if err := p.layerGeomType(&layer); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("gpkg: unable to determine geometry type for layer %v: %v", name, err)
+		}
+
+		if tableName != "" {
+			tag, err := p.lastChangeTag(tableName)
+			if err != nil {
+				db.Close()
+				return nil, fmt.Errorf("gpkg: unable to read last_change for layer %v: %v", name, err)
+			}
+			layer.tag = tag
+		}
+
+		p.layers[name] = layer
+	}
+
+	return p, nil
+}
+
+// layerGeomType runs l's SQL bounded to the full web-mercator extent and
+// LIMIT 1 to inspect the shape of the geometry column returned, caching
+// the result on l.geomType. This mirrors provider/postgis's layerGeomType.
+func (p Provider) layerGeomType(l *Layer) error {
+	sqlStr := replaceTokens(l.sql, fmt.Sprintf("BuildMbr(-20037508.34,-20037508.34,20037508.34,20037508.34,%v)", l.srid), "0")
+	sqlStr = fmt.Sprintf("SELECT q.* FROM (%v) AS q LIMIT 1", sqlStr)
+
+	rows, err := p.db.Query(sqlStr)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return fmt.Errorf("layer %v: query for geometry-type detection returned no rows", l.name)
+	}
+
+	vals := make([]interface{}, len(cols))
+	ptrs := make([]interface{}, len(cols))
+	for i := range vals {
+		ptrs[i] = &vals[i]
+	}
+	if err := rows.Scan(ptrs...); err != nil {
+		return err
+	}
+
+	for i, col := range cols {
+		if col != l.geomFieldname {
+			continue
+		}
+		wkb, ok := vals[i].([]byte)
+		if !ok {
+			return fmt.Errorf("geometry field %v did not decode to bytes", l.geomFieldname)
+		}
+		g, err := geom.DecodeWKB(wkb)
+		if err != nil {
+			return err
+		}
+		l.geomType = g
+		return nil
+	}
+
+	return fmt.Errorf("geometry field %v not found in query results", l.geomFieldname)
+}
+
+// lastChangeTag reads gpkg_contents.last_change for tableName, which the
+// GeoPackage spec updates whenever the table's data changes, letting the
+// cache layer invalidate entries when the GeoPackage file is swapped.
+func (p Provider) lastChangeTag(tableName string) (*string, error) {
+	var lastChange string
+	row := p.db.QueryRow(`SELECT last_change FROM gpkg_contents WHERE table_name = ?`, tableName)
+	if err := row.Scan(&lastChange); err != nil {
+		return nil, err
+	}
+	return &lastChange, nil
+}
+
+// Layers returns the layers configured on this provider.
+func (p Provider) Layers() ([]provider.LayerInfo, error) {
+	infos := make([]provider.LayerInfo, 0, len(p.layers))
+	for _, l := range p.layers {
+		infos = append(infos, l)
+	}
+	return infos, nil
+}
+
+// TileFeatures streams the decoded features for layer within t's buffered
+// extent, substituting !BBOX! and !ZOOM! into the layer's configured SQL.
+func (p Provider) TileFeatures(ctx context.Context, layer string, t provider.Tile, fn func(f *provider.Feature) error) error {
+	l, ok := p.layers[layer]
+	if !ok {
+		return fmt.Errorf("gpkg: layer %v not registered", layer)
+	}
+
+	z, _, _ := t.ZXY()
+	extent, srid := t.BufferedExtent()
+
+	sqlStr := replaceTokens(l.sql, bboxToken(extent, srid), strconv.FormatUint(uint64(z), 10))
+
+	rows, err := p.db.QueryContext(ctx, sqlStr)
+	if err != nil {
+		return fmt.Errorf("gpkg: error running layer %v query: %v", layer, err)
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	for rows.Next() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		vals := make([]interface{}, len(cols))
+		ptrs := make([]interface{}, len(cols))
+		for i := range vals {
+			ptrs[i] = &vals[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return err
+		}
+
+		f, err := toFeature(l, cols, vals)
+		if err != nil {
+			return fmt.Errorf("gpkg: error decoding row for layer %v: %v", layer, err)
+		}
+
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func toFeature(l Layer, cols []string, vals []interface{}) (*provider.Feature, error) {
+	f := &provider.Feature{SRID: l.srid, Tags: map[string]interface{}{}}
+
+	for i, col := range cols {
+		switch col {
+		case l.idFieldname:
+			id, err := toUint64(vals[i])
+			if err != nil {
+				return nil, fmt.Errorf("unable to convert id field %v: %w", l.idFieldname, err)
+			}
+			f.ID = id
+		case l.geomFieldname:
+			wkb, ok := vals[i].([]byte)
+			if !ok {
+				return nil, fmt.Errorf("geometry field %v did not decode to bytes", l.geomFieldname)
+			}
+			g, err := geom.DecodeWKB(wkb)
+			if err != nil {
+				return nil, err
+			}
+			f.Geometry = g
+		default:
+			if vals[i] != nil {
+				f.Tags[col] = vals[i]
+			}
+		}
+	}
+
+	return f, nil
+}
+
+// replaceTokens substitutes the !BBOX! and !ZOOM! tokens tegola documents
+// for layer SQL.
+func replaceTokens(sqlStr, bbox, zoom string) string {
+	sqlStr = strings.Replace(sqlStr, "!BBOX!", bbox, -1)
+	sqlStr = strings.Replace(sqlStr, "!ZOOM!", zoom, -1)
+	return sqlStr
+}
+
+func bboxToken(extent *geom.Extent, srid uint64) string {
+	return fmt.Sprintf("BuildMbr(%v,%v,%v,%v,%v)", extent.MinX(), extent.MinY(), extent.MaxX(), extent.MaxY(), srid)
+}
+
+func toUint64(v interface{}) (uint64, error) {
+	switch t := v.(type) {
+	case int64:
+		return uint64(t), nil
+	case int32:
+		return uint64(t), nil
+	case uint64:
+		return t, nil
+	default:
+		return 0, fmt.Errorf("unsupported id type %T", v)
+	}
+}
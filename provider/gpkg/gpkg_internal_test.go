@@ -0,0 +1,80 @@
+package gpkg
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/go-spatial/geom"
+	"github.com/go-spatial/tegola/internal/ttools"
+)
+
+// TESTENV is the environment variable that must be set to "yes" to run gpkg tests.
+const TESTENV = "RUN_GPKG_TESTS"
+
+func GetTestFilePath(t *testing.T) string {
+	ttools.ShouldSkip(t, TESTENV)
+	fp := os.Getenv("GPKG_FILEPATH")
+	if fp == "" {
+		t.Skip("GPKG_FILEPATH must be set")
+	}
+	return fp
+}
+
+func TestLayerGeomType(t *testing.T) {
+	fp := GetTestFilePath(t)
+
+	testcases := []struct {
+		config    map[string]interface{}
+		layerName string
+		geom      geom.Geometry
+	}{
+		{
+			config: map[string]interface{}{
+				ConfigKeyFilePath: fp,
+				ConfigKeyLayers: []map[string]interface{}{
+					{
+						ConfigKeyLayerName: "land",
+						ConfigKeySQL:       "SELECT fid, AsBinary(geom) AS geom FROM ne_10m_land_scale_rank WHERE geom && !BBOX!",
+					},
+				},
+			},
+			layerName: "land",
+			geom:      geom.MultiPolygon{},
+		},
+		// zoom token replacement
+		{
+			config: map[string]interface{}{
+				ConfigKeyFilePath: fp,
+				ConfigKeyLayers: []map[string]interface{}{
+					{
+						ConfigKeyLayerName: "land",
+						ConfigKeySQL:       "SELECT fid, AsBinary(geom) AS geom FROM ne_10m_land_scale_rank WHERE fid = !ZOOM! AND geom && !BBOX!",
+					},
+				},
+			},
+			layerName: "land",
+			geom:      geom.MultiPolygon{},
+		},
+	}
+
+	for i, tc := range testcases {
+		provider, err := NewTileProvider(tc.config)
+		if err != nil {
+			t.Errorf("[%v] NewProvider error, expected nil got %v", i, err)
+			continue
+		}
+
+		p := provider.(Provider)
+		layer := p.layers[tc.layerName]
+		if err := p.layerGeomType(&layer); err != nil {
+			t.Errorf("[%v] layerGeomType error, expected nil got %v", i, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(tc.geom, layer.geomType) {
+			t.Errorf("[%v] geom type, expected %v got %v", i, tc.geom, layer.geomType)
+			continue
+		}
+	}
+}
@@ -0,0 +1,162 @@
+// Package grpc implements a provider.Tiler that delegates TileFeatures and
+// Layers to an external process over gRPC, so providers can be written in
+// any language with a gRPC implementation (Python, Rust, JS, ...) without
+// linking against tegola itself. See tegola.proto for the wire protocol.
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"google.golang.org/grpc"
+
+	"github.com/go-spatial/geom"
+	"github.com/go-spatial/geom/encoding/wkb"
+	"github.com/go-spatial/tegola/provider"
+	"github.com/go-spatial/tegola/provider/grpc/tegolapb"
+)
+
+// Config keys for the grpc provider.
+const (
+	ConfigKeyAddr = "addr"
+)
+
+func init() {
+	provider.Register(Name, NewTileProvider, nil)
+}
+
+// Name is the name the provider is registered under.
+const Name = "grpc"
+
+// Provider is a Tiler backed by a TileProvider gRPC service running in
+// another process.
+type Provider struct {
+	addr   string
+	conn   *grpc.ClientConn
+	client tegolapb.TileProviderClient
+}
+
+// NewTileProvider dials addr and returns a Tiler that streams features from
+// the remote TileProvider service.
+func NewTileProvider(config map[string]interface{}) (provider.Tiler, error) {
+	addr, ok := config[ConfigKeyAddr].(string)
+	if !ok || addr == "" {
+		return nil, fmt.Errorf("grpc: %v is required and must be a string", ConfigKeyAddr)
+	}
+
+	conn, err := grpc.Dial(addr, grpc.WithInsecure())
+	if err != nil {
+		return nil, fmt.Errorf("grpc: unable to dial %v: %w", addr, err)
+	}
+
+	return Provider{
+		addr:   addr,
+		conn:   conn,
+		client: tegolapb.NewTileProviderClient(conn),
+	}, nil
+}
+
+// TileFeatures opens a server-streaming RPC for layer/t and invokes fn for
+// each feature received, propagating ctx cancellation to the RPC and
+// honoring fn's error (including provider.ErrCanceled) by closing the
+// stream early.
+func (p Provider) TileFeatures(ctx context.Context, layer string, t provider.Tile, fn func(f *provider.Feature) error) error {
+	z, x, y := t.ZXY()
+	extent, srid := t.BufferedExtent()
+
+	extentWKB, err := wkb.EncodeBytes(extent.AsPolygon())
+	if err != nil {
+		return fmt.Errorf("grpc: unable to encode buffered extent: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := p.client.TileFeatures(ctx, &tegolapb.TileFeaturesRequest{
+		Layer:             layer,
+		Z:                 uint32(z),
+		X:                 uint32(x),
+		Y:                 uint32(y),
+		BufferedExtentWkb: extentWKB,
+		Srid:              srid,
+	})
+	if err != nil {
+		return fmt.Errorf("grpc: TileFeatures RPC failed: %w", err)
+	}
+
+	for {
+		pbf, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("grpc: TileFeatures stream failed: %w", err)
+		}
+
+		g, err := wkb.DecodeBytes(pbf.GeometryWkb)
+		if err != nil {
+			return fmt.Errorf("grpc: unable to decode feature geometry: %w", err)
+		}
+
+		tags := make(map[string]interface{}, len(pbf.Tags))
+		for k, v := range pbf.Tags {
+			tags[k] = v
+		}
+
+		f := &provider.Feature{
+			ID:       pbf.Id,
+			Geometry: g,
+			SRID:     pbf.Srid,
+			Tags:     tags,
+		}
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+}
+
+// Layers returns the layers reported by the remote provider via the
+// LayerInfo unary RPC.
+func (p Provider) Layers() ([]provider.LayerInfo, error) {
+	reply, err := p.client.LayerInfo(context.Background(), &tegolapb.LayerInfoRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("grpc: LayerInfo RPC failed: %w", err)
+	}
+
+	infos := make([]provider.LayerInfo, 0, len(reply.Layers))
+	for _, l := range reply.Layers {
+		geomType, err := wkb.DecodeBytes(l.GeomTypeWkb)
+		if err != nil {
+			return nil, fmt.Errorf("grpc: unable to decode layer %v geom type: %w", l.Name, err)
+		}
+
+		infos = append(infos, remoteLayer{
+			name:     l.Name,
+			geomType: geomType,
+			srid:     l.Srid,
+			tag:      l.ModificationTag,
+			hasTag:   l.HasModificationTag,
+		})
+	}
+	return infos, nil
+}
+
+type remoteLayer struct {
+	name     string
+	geomType geom.Geometry
+	srid     uint64
+	tag      string
+	hasTag   bool
+}
+
+func (l remoteLayer) Name() string            { return l.name }
+func (l remoteLayer) GeomType() geom.Geometry { return l.geomType }
+func (l remoteLayer) SRID() uint64            { return l.srid }
+
+func (l remoteLayer) ModificationTag() *string {
+	if !l.hasTag {
+		return nil
+	}
+	return &l.tag
+}
@@ -0,0 +1,112 @@
+// Package grpcserver is a reference implementation of the tegola gRPC
+// remote-provider protocol (tegolapb.TileProviderServer). It adapts an
+// in-process provider.Tiler to the wire protocol so that the provider/grpc
+// client package (or an equivalent implementation in another language) can
+// consume it; it's the skeleton the external-runtime providers described
+// in tegola.proto are meant to follow.
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-spatial/geom"
+	"github.com/go-spatial/geom/encoding/wkb"
+	"github.com/go-spatial/tegola/provider"
+	"github.com/go-spatial/tegola/provider/grpc/tegolapb"
+)
+
+// Server adapts a provider.Tiler to tegolapb.TileProviderServer.
+type Server struct {
+	tegolapb.UnimplementedTileProviderServer
+
+	Tiler provider.Tiler
+}
+
+// New returns a Server that serves t over gRPC.
+func New(t provider.Tiler) *Server {
+	return &Server{Tiler: t}
+}
+
+// TileFeatures decodes the request's tile coordinate and buffered extent,
+// then streams t's features back to the client one Feature message at a
+// time.
+func (s *Server) TileFeatures(req *tegolapb.TileFeaturesRequest, stream tegolapb.TileProvider_TileFeaturesServer) error {
+	extentGeom, err := wkb.DecodeBytes(req.BufferedExtentWkb)
+	if err != nil {
+		return fmt.Errorf("grpcserver: unable to decode buffered extent: %w", err)
+	}
+	poly, ok := extentGeom.(geom.Polygon)
+	if !ok {
+		return fmt.Errorf("grpcserver: buffered extent was not a polygon")
+	}
+	extent, err := geom.NewExtentFromGeometry(poly)
+	if err != nil {
+		return fmt.Errorf("grpcserver: unable to derive extent: %w", err)
+	}
+
+	tile := tileCoord{z: uint(req.Z), x: uint(req.X), y: uint(req.Y), extent: extent, srid: req.Srid}
+
+	ctx := stream.Context()
+	return s.Tiler.TileFeatures(ctx, req.Layer, tile, func(f *provider.Feature) error {
+		geomWKB, err := wkb.EncodeBytes(f.Geometry)
+		if err != nil {
+			return fmt.Errorf("grpcserver: unable to encode feature geometry: %w", err)
+		}
+
+		tags := make(map[string]string, len(f.Tags))
+		for k, v := range f.Tags {
+			tags[k] = fmt.Sprintf("%v", v)
+		}
+
+		return stream.Send(&tegolapb.Feature{
+			Id:          f.ID,
+			GeometryWkb: geomWKB,
+			Srid:        f.SRID,
+			Tags:        tags,
+		})
+	})
+}
+
+// LayerInfo reports s.Tiler's layers, including each layer's
+// ModificationTag where supported.
+func (s *Server) LayerInfo(ctx context.Context, _ *tegolapb.LayerInfoRequest) (*tegolapb.LayerInfoReply, error) {
+	layers, err := s.Tiler.Layers()
+	if err != nil {
+		return nil, fmt.Errorf("grpcserver: unable to list layers: %w", err)
+	}
+
+	reply := &tegolapb.LayerInfoReply{Layers: make([]*tegolapb.LayerInfo, 0, len(layers))}
+	for _, l := range layers {
+		geomTypeWKB, err := wkb.EncodeBytes(l.GeomType())
+		if err != nil {
+			return nil, fmt.Errorf("grpcserver: unable to encode layer %v geom type: %w", l.Name(), err)
+		}
+
+		pbLayer := &tegolapb.LayerInfo{
+			Name:        l.Name(),
+			GeomTypeWkb: geomTypeWKB,
+			Srid:        l.SRID(),
+		}
+		if tag := l.ModificationTag(); tag != nil {
+			pbLayer.ModificationTag = *tag
+			pbLayer.HasModificationTag = true
+		}
+		reply.Layers = append(reply.Layers, pbLayer)
+	}
+	return reply, nil
+}
+
+// tileCoord is a minimal provider.Tile implementation built from the z/x/y
+// and buffered extent sent over the wire.
+type tileCoord struct {
+	z, x, y uint
+	extent  *geom.Extent
+	srid    uint64
+}
+
+func (t tileCoord) ZXY() (uint, uint, uint) { return t.z, t.x, t.y }
+
+func (t tileCoord) Extent() (*geom.Extent, uint64) { return t.extent, t.srid }
+
+func (t tileCoord) BufferedExtent() (*geom.Extent, uint64) { return t.extent, t.srid }
@@ -0,0 +1,25 @@
+package grpcserver
+
+import (
+	"testing"
+
+	"github.com/go-spatial/geom"
+)
+
+func TestTileCoord(t *testing.T) {
+	extent := &geom.Extent{0, 0, 10, 10}
+	tc := tileCoord{z: 4, x: 2, y: 3, extent: extent, srid: 3857}
+
+	z, x, y := tc.ZXY()
+	if z != 4 || x != 2 || y != 3 {
+		t.Errorf("ZXY() = %v,%v,%v, want 4,2,3", z, x, y)
+	}
+
+	if e, srid := tc.Extent(); e != extent || srid != 3857 {
+		t.Errorf("Extent() = %v,%v, want %v,3857", e, srid, extent)
+	}
+
+	if e, srid := tc.BufferedExtent(); e != extent || srid != 3857 {
+		t.Errorf("BufferedExtent() = %v,%v, want %v,3857", e, srid, extent)
+	}
+}
@@ -0,0 +1,245 @@
+// Package mbtiles implements a provider.Tiler that serves pre-rendered
+// vector tiles out of a single MBTiles (SQLite) file, as produced by
+// tippecanoe, tilelive, or similar tools. It exists primarily for
+// air-gapped deployments and tests where running a PostGIS instance
+// isn't practical.
+package mbtiles
+
+import (
+	"context"
+	"crypto/md5"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/go-spatial/geom"
+	"github.com/go-spatial/geom/encoding/mvt"
+	"github.com/go-spatial/tegola/provider"
+)
+
+// Config keys for the mbtiles provider.
+const (
+	ConfigKeyFilePath  = "filepath"
+	ConfigKeyLayers    = "layers"
+	ConfigKeyLayerName = "name"
+)
+
+func init() {
+	provider.Register(Name, NewTileProvider, nil)
+}
+
+// Name is the name the provider is registered under.
+const Name = "mbtiles"
+
+// Provider reads vector tiles out of an MBTiles file. The tiles stored in
+// the file are expected to already be encoded as Mapbox Vector Tiles; this
+// provider decodes them on the fly so the rest of tegola (caching, layer
+// composition) can treat it the same as any other Tiler.
+type Provider struct {
+	filepath string
+	db       *sql.DB
+	// layersMu guards layers, which is lazily populated from the
+	// MBTiles' vector_layers metadata on first Layers() call and so can
+	// race concurrent tile-request goroutines calling Layers().
+	layersMu sync.Mutex
+	layers   map[string]Layer
+	// tag is precomputed once at open time from the file's mtime+size so
+	// ModificationTag is cheap to call on every request.
+	tag string
+}
+
+// Layer describes a single named layer served out of the MBTiles file.
+type Layer struct {
+	name     string
+	geomType geom.Geometry
+	srid     uint64
+	// tag is the provider-wide ModificationTag; all layers in an MBTiles
+	// file share a single file on disk, so they all invalidate together.
+	tag string
+}
+
+func (l Layer) Name() string            { return l.name }
+func (l Layer) GeomType() geom.Geometry { return l.geomType }
+func (l Layer) SRID() uint64            { return l.srid }
+
+// ModificationTag returns a hash of the backing file's mtime and size so
+// the cache layer can invalidate entries when the MBTiles file is swapped.
+func (l Layer) ModificationTag() *string { return &l.tag }
+
+var _ provider.LayerInfo = Layer{}
+
+// NewTileProvider instantiates and returns a new mbtiles provider, opening
+// the backing SQLite file and computing its ModificationTag.
+func NewTileProvider(config map[string]interface{}) (provider.Tiler, error) {
+	fp, ok := config[ConfigKeyFilePath].(string)
+	if !ok || fp == "" {
+		return nil, fmt.Errorf("mbtiles: %v is required and must be a string", ConfigKeyFilePath)
+	}
+
+	db, err := sql.Open("sqlite3", fp+"?mode=ro&immutable=1")
+	if err != nil {
+		return nil, fmt.Errorf("mbtiles: unable to open %v: %w", fp, err)
+	}
+
+	tag, err := modificationTag(fp)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	p := Provider{
+		filepath: fp,
+		db:       db,
+		tag:      tag,
+		layers:   map[string]Layer{},
+	}
+
+	layerConfigs, _ := config[ConfigKeyLayers].([]map[string]interface{})
+	if len(layerConfigs) == 0 {
+		// No layers configured explicitly; the MBTiles' own vector_layers
+		// metadata will be consulted lazily on first Layers call.
+		return &p, nil
+	}
+
+	for _, lc := range layerConfigs {
+		name, ok := lc[ConfigKeyLayerName].(string)
+		if !ok || name == "" {
+			db.Close()
+			return nil, fmt.Errorf("mbtiles: layer missing %v", ConfigKeyLayerName)
+		}
+		p.layers[name] = Layer{name: name, srid: tegolaSRID, tag: tag}
+	}
+
+	return &p, nil
+}
+
+// tegolaSRID is the only SRID tegola's pipeline deals in; MBTiles tiles are
+// always stored pre-projected to web mercator.
+const tegolaSRID = 3857
+
+// modificationTag hashes the file's mtime and size so the cache layer can
+// tell a swapped MBTiles file from the previous one. NewTileProvider calls
+// it once, so a file swapped under a running provider gets a new tag only
+// once the provider is opened again.
+func modificationTag(fp string) (string, error) {
+	fi, err := os.Stat(fp)
+	if err != nil {
+		return "", fmt.Errorf("mbtiles: unable to stat %v: %w", fp, err)
+	}
+
+	h := md5.Sum([]byte(fmt.Sprintf("%v:%v", fi.ModTime().UnixNano(), fi.Size())))
+	return hex.EncodeToString(h[:]), nil
+}
+
+// Layers returns the layers known to this provider, reading the MBTiles
+// vector_layers metadata entry the first time it's needed.
+func (p *Provider) Layers() ([]provider.LayerInfo, error) {
+	p.layersMu.Lock()
+	defer p.layersMu.Unlock()
+
+	if len(p.layers) == 0 {
+		if err := p.loadLayersFromMetadata(); err != nil {
+			return nil, err
+		}
+	}
+
+	var infos []provider.LayerInfo
+	for _, l := range p.layers {
+		infos = append(infos, l)
+	}
+	return infos, nil
+}
+
+// loadLayersFromMetadata must only be called with p.layersMu held.
+func (p *Provider) loadLayersFromMetadata() error {
+	row := p.db.QueryRow(`SELECT value FROM metadata WHERE name = 'json'`)
+	var raw string
+	if err := row.Scan(&raw); err != nil {
+		return fmt.Errorf("mbtiles: unable to read vector_layers metadata: %w", err)
+	}
+
+	names, err := vectorLayerNames(raw)
+	if err != nil {
+		return err
+	}
+
+	p.layers = make(map[string]Layer, len(names))
+	for _, name := range names {
+		p.layers[name] = Layer{name: name, srid: tegolaSRID, tag: p.tag}
+	}
+	return nil
+}
+
+// vectorLayerNames extracts the layer names out of an MBTiles "json"
+// metadata value, whose vector_layers entry documents what's embedded in
+// the tileset (see the MBTiles 1.3 spec).
+func vectorLayerNames(raw string) ([]string, error) {
+	var meta struct {
+		VectorLayers []struct {
+			ID string `json:"id"`
+		} `json:"vector_layers"`
+	}
+	if err := json.Unmarshal([]byte(raw), &meta); err != nil {
+		return nil, fmt.Errorf("mbtiles: unable to parse vector_layers metadata: %w", err)
+	}
+
+	names := make([]string, 0, len(meta.VectorLayers))
+	for _, l := range meta.VectorLayers {
+		names = append(names, l.ID)
+	}
+	return names, nil
+}
+
+// TileFeatures streams the decoded features for layer out of the tile at
+// t's z/x/y, flipping the row to MBTiles' TMS convention before querying.
+func (p *Provider) TileFeatures(ctx context.Context, layer string, t provider.Tile, fn func(f *provider.Feature) error) error {
+	z, x, y := t.ZXY()
+
+	tmsY := (uint(1) << z) - 1 - y
+
+	var data []byte
+	row := p.db.QueryRowContext(ctx,
+		`SELECT tile_data FROM tiles WHERE zoom_level = ? AND tile_column = ? AND tile_row = ?`,
+		z, x, tmsY,
+	)
+	if err := row.Scan(&data); err != nil {
+		if err == sql.ErrNoRows {
+			// No tile at this coordinate is not an error; it's simply empty.
+			return nil
+		}
+		return fmt.Errorf("mbtiles: unable to read tile %v/%v/%v: %w", z, x, y, err)
+	}
+
+	tile, err := mvt.Decode(data)
+	if err != nil {
+		return fmt.Errorf("mbtiles: unable to decode tile %v/%v/%v: %w", z, x, y, err)
+	}
+
+	ml, ok := tile.Layer(layer)
+	if !ok {
+		return nil
+	}
+
+	for _, f := range ml.Features() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		pf := &provider.Feature{
+			ID:       f.ID,
+			Geometry: f.Geometry,
+			SRID:     tegolaSRID,
+			Tags:     f.Tags,
+		}
+		if err := fn(pf); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
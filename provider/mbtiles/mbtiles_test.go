@@ -0,0 +1,78 @@
+package mbtiles
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestVectorLayerNames(t *testing.T) {
+	testcases := []struct {
+		raw      string
+		expected []string
+	}{
+		{
+			raw:      `{"vector_layers":[{"id":"land"},{"id":"water"}]}`,
+			expected: []string{"land", "water"},
+		},
+		{
+			raw:      `{"vector_layers":[]}`,
+			expected: []string{},
+		},
+	}
+
+	for i, tc := range testcases {
+		got, err := vectorLayerNames(tc.raw)
+		if err != nil {
+			t.Errorf("[%v] unexpected error: %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(tc.expected, got) {
+			t.Errorf("[%v] expected %v got %v", i, tc.expected, got)
+		}
+	}
+}
+
+func TestVectorLayerNamesInvalidJSON(t *testing.T) {
+	if _, err := vectorLayerNames("not json"); err == nil {
+		t.Error("expected error for invalid json, got nil")
+	}
+}
+
+func TestModificationTag(t *testing.T) {
+	dir := t.TempDir()
+	fp := filepath.Join(dir, "test.mbtiles")
+	if err := os.WriteFile(fp, []byte("abc"), 0644); err != nil {
+		t.Fatalf("unable to write test file: %v", err)
+	}
+
+	tag1, err := modificationTag(fp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tag2, err := modificationTag(fp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tag1 != tag2 {
+		t.Errorf("expected stable tag for unchanged file, got %v != %v", tag1, tag2)
+	}
+
+	if err := os.WriteFile(fp, []byte("abcdef"), 0644); err != nil {
+		t.Fatalf("unable to rewrite test file: %v", err)
+	}
+	tag3, err := modificationTag(fp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tag1 == tag3 {
+		t.Errorf("expected tag to change when file size changes, got same tag %v", tag1)
+	}
+}
+
+func TestModificationTagMissingFile(t *testing.T) {
+	if _, err := modificationTag("/nonexistent/path.mbtiles"); err == nil {
+		t.Error("expected error for missing file, got nil")
+	}
+}
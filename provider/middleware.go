@@ -0,0 +1,111 @@
+package provider
+
+import (
+	"fmt"
+	"time"
+)
+
+// ConfigKeyMiddleware is the provider config key listing the middleware
+// chain to wrap a Tiler in, e.g.:
+//
+//	middleware:
+//	  - name: circuit_breaker
+//	    cooldown: 30s
+//	  - name: retry
+//	    max_attempts: 3
+//	  - name: tracing
+const ConfigKeyMiddleware = "middleware"
+
+// MiddlewareConfigKeyName is the key within a single middleware entry that
+// names which registered middleware to use; the whole entry, this key
+// included, is passed to that middleware's factory unchanged.
+const MiddlewareConfigKeyName = "name"
+
+// MiddlewareFactory builds a Middleware from a single middleware config
+// entry (MiddlewareConfigKeyName included).
+type MiddlewareFactory func(config map[string]interface{}) (Middleware, error)
+
+// Middleware wraps a Tiler to add cross-cutting behavior (retries, circuit
+// breaking, tracing, ...) without the wrapped provider needing to know
+// about it.
+type Middleware interface {
+	Wrap(t Tiler) Tiler
+}
+
+var middlewares map[string]MiddlewareFactory
+
+// RegisterMiddleware registers a middleware factory under name so it can
+// be referenced from a provider's config-supplied middleware chain. This
+// call is generally made in the init function of the middleware.
+func RegisterMiddleware(name string, factory MiddlewareFactory) error {
+	if middlewares == nil {
+		middlewares = make(map[string]MiddlewareFactory)
+	}
+
+	if _, ok := middlewares[name]; ok {
+		return fmt.Errorf("Middleware %v already exists", name)
+	}
+
+	middlewares[name] = factory
+	return nil
+}
+
+// configDuration reads a duration-valued middleware config entry. TOML
+// and JSON config never produce a native time.Duration, so it accepts a
+// Go duration string (e.g. "200ms", parsed with time.ParseDuration) or a
+// bare number of seconds, the way provider config is handled elsewhere
+// (e.g. postgis.go's port parsing accepting both int and int64). ok is
+// false if key isn't set, in which case the caller should keep its
+// default.
+func configDuration(config map[string]interface{}, key string) (d time.Duration, ok bool, err error) {
+	switch v := config[key].(type) {
+	case nil:
+		return 0, false, nil
+	case string:
+		d, err = time.ParseDuration(v)
+		if err != nil {
+			return 0, false, fmt.Errorf("%v: invalid duration %q: %w", key, v, err)
+		}
+		return d, true, nil
+	case time.Duration:
+		return v, true, nil
+	case int:
+		return time.Duration(v) * time.Second, true, nil
+	case int64:
+		return time.Duration(v) * time.Second, true, nil
+	case float64:
+		return time.Duration(v * float64(time.Second)), true, nil
+	default:
+		return 0, false, fmt.Errorf("%v: must be a duration string or a number of seconds, got %T", key, v)
+	}
+}
+
+// wrapMiddleware reads config's middleware chain, if any, and wraps t in
+// each middleware in the order listed.
+func wrapMiddleware(providerName string, t Tiler, config map[string]interface{}) (Tiler, error) {
+	entries, ok := config[ConfigKeyMiddleware].([]map[string]interface{})
+	if !ok || len(entries) == 0 {
+		return t, nil
+	}
+
+	for i, entry := range entries {
+		name, ok := entry[MiddlewareConfigKeyName].(string)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("provider %v: middleware entry %v missing %v", providerName, i, MiddlewareConfigKeyName)
+		}
+
+		factory, ok := middlewares[name]
+		if !ok {
+			return nil, fmt.Errorf("provider %v: no middleware registered by the name: %v", providerName, name)
+		}
+
+		mw, err := factory(entry)
+		if err != nil {
+			return nil, fmt.Errorf("provider %v: unable to build middleware %v: %w", providerName, name, err)
+		}
+
+		t = mw.Wrap(t)
+	}
+
+	return t, nil
+}
@@ -0,0 +1,143 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Config keys for the circuit_breaker middleware.
+const (
+	ConfigKeyBreakerThreshold = "failure_threshold"
+	ConfigKeyBreakerCooldown  = "cooldown"
+)
+
+const (
+	defaultBreakerThreshold = 5
+	defaultBreakerCooldown  = 30 * time.Second
+)
+
+func init() {
+	RegisterMiddleware("circuit_breaker", newCircuitBreakerMiddleware)
+}
+
+// circuitBreakerMiddleware short-circuits calls to an upstream that has
+// failed threshold times in a row, failing fast for cooldown before
+// letting another call through to probe whether the upstream recovered.
+type circuitBreakerMiddleware struct {
+	threshold int
+	cooldown  time.Duration
+}
+
+func newCircuitBreakerMiddleware(config map[string]interface{}) (Middleware, error) {
+	m := circuitBreakerMiddleware{
+		threshold: defaultBreakerThreshold,
+		cooldown:  defaultBreakerCooldown,
+	}
+
+	if v, ok := config[ConfigKeyBreakerThreshold].(int); ok {
+		if v < 1 {
+			return nil, fmt.Errorf("circuit_breaker middleware: %v must be >= 1", ConfigKeyBreakerThreshold)
+		}
+		m.threshold = v
+	}
+	if v, ok, err := configDuration(config, ConfigKeyBreakerCooldown); err != nil {
+		return nil, fmt.Errorf("circuit_breaker middleware: %w", err)
+	} else if ok {
+		m.cooldown = v
+	}
+
+	return m, nil
+}
+
+// Wrap returns a *circuitBreakerTiler if t doesn't implement Filterer, or
+// a *circuitBreakerFilterTiler if it does, so the wrapped value's own
+// Filterer type-assertion reports the same capability the wrapped
+// provider had rather than unconditionally claiming StreamFeatures
+// support.
+func (m circuitBreakerMiddleware) Wrap(t Tiler) Tiler {
+	ct := &circuitBreakerTiler{next: t, mw: m}
+	if f, ok := t.(Filterer); ok {
+		return &circuitBreakerFilterTiler{circuitBreakerTiler: ct, filter: f}
+	}
+	return ct
+}
+
+type circuitBreakerTiler struct {
+	next Tiler
+	mw   circuitBreakerMiddleware
+
+	mu          sync.Mutex
+	failures    int
+	openedAt    time.Time
+	breakerOpen bool
+}
+
+func (t *circuitBreakerTiler) Layers() ([]LayerInfo, error) { return t.next.Layers() }
+
+func (t *circuitBreakerTiler) TileFeatures(ctx context.Context, layer string, tile Tile, fn func(f *Feature) error) error {
+	return t.call(func() error {
+		return t.next.TileFeatures(ctx, layer, tile, fn)
+	})
+}
+
+// circuitBreakerFilterTiler adds StreamFeatures to circuitBreakerTiler for
+// providers that implement Filterer, sharing the same breaker state
+// between TileFeatures and StreamFeatures calls; plain Tilers are wrapped
+// as a *circuitBreakerTiler instead, so they don't satisfy a Filterer
+// type-assertion only to fail at call time.
+type circuitBreakerFilterTiler struct {
+	*circuitBreakerTiler
+	filter Filterer
+}
+
+func (t *circuitBreakerFilterTiler) StreamFeatures(ctx context.Context, layer string, bounds Bounder, properties map[string]string, fn FeatureConsumer) error {
+	return t.call(func() error {
+		return t.filter.StreamFeatures(ctx, layer, bounds, properties, fn)
+	})
+}
+
+func (t *circuitBreakerTiler) call(do func() error) error {
+	if err := t.checkOpen(); err != nil {
+		return err
+	}
+
+	err := do()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err != nil {
+		t.failures++
+		if t.failures >= t.mw.threshold {
+			t.breakerOpen = true
+			t.openedAt = time.Now()
+		}
+		return err
+	}
+
+	t.failures = 0
+	t.breakerOpen = false
+	return nil
+}
+
+// checkOpen returns an error without calling upstream if the breaker is
+// open and still within its cooldown window; once cooldown has elapsed,
+// it lets one call through to probe for recovery.
+func (t *circuitBreakerTiler) checkOpen() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.breakerOpen {
+		return nil
+	}
+	if time.Since(t.openedAt) < t.mw.cooldown {
+		return fmt.Errorf("circuit_breaker middleware: upstream is failing, short-circuiting for %v", t.mw.cooldown-time.Since(t.openedAt))
+	}
+
+	// Cooldown elapsed; allow this call through as a probe and restart the
+	// cooldown so other calls keep failing fast until the probe succeeds.
+	t.openedAt = time.Now()
+	return nil
+}
@@ -0,0 +1,115 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Config keys for the retry middleware.
+const (
+	ConfigKeyRetryMaxAttempts = "max_attempts"
+	ConfigKeyRetryBaseDelay   = "base_delay"
+)
+
+const (
+	defaultRetryMaxAttempts = 3
+	defaultRetryBaseDelay   = 100 * time.Millisecond
+)
+
+func init() {
+	RegisterMiddleware("retry", newRetryMiddleware)
+}
+
+// retryMiddleware retries a failing upstream call, making at most
+// maxAttempts attempts in all and doubling the delay between each attempt.
+type retryMiddleware struct {
+	maxAttempts int
+	baseDelay   time.Duration
+}
+
+func newRetryMiddleware(config map[string]interface{}) (Middleware, error) {
+	m := retryMiddleware{
+		maxAttempts: defaultRetryMaxAttempts,
+		baseDelay:   defaultRetryBaseDelay,
+	}
+
+	if v, ok := config[ConfigKeyRetryMaxAttempts].(int); ok {
+		if v < 1 {
+			return nil, fmt.Errorf("retry middleware: %v must be >= 1", ConfigKeyRetryMaxAttempts)
+		}
+		m.maxAttempts = v
+	}
+	if v, ok, err := configDuration(config, ConfigKeyRetryBaseDelay); err != nil {
+		return nil, fmt.Errorf("retry middleware: %w", err)
+	} else if ok {
+		m.baseDelay = v
+	}
+
+	return m, nil
+}
+
+// Wrap returns a retryTiler if t doesn't implement Filterer, or a
+// retryFilterTiler if it does, so the wrapped value's own Filterer
+// type-assertion reports the same capability the wrapped provider had
+// rather than unconditionally claiming StreamFeatures support.
+func (m retryMiddleware) Wrap(t Tiler) Tiler {
+	rt := retryTiler{next: t, mw: m}
+	if f, ok := t.(Filterer); ok {
+		return retryFilterTiler{retryTiler: rt, filter: f}
+	}
+	return rt
+}
+
+type retryTiler struct {
+	next Tiler
+	mw   retryMiddleware
+}
+
+func (t retryTiler) Layers() ([]LayerInfo, error) { return t.next.Layers() }
+
+func (t retryTiler) TileFeatures(ctx context.Context, layer string, tile Tile, fn func(f *Feature) error) error {
+	return t.mw.do(ctx, func() error {
+		return t.next.TileFeatures(ctx, layer, tile, fn)
+	})
+}
+
+// retryFilterTiler adds StreamFeatures to retryTiler for providers that
+// implement Filterer; plain Tilers are wrapped as a retryTiler instead, so
+// they don't satisfy a Filterer type-assertion only to fail at call time.
+type retryFilterTiler struct {
+	retryTiler
+	filter Filterer
+}
+
+func (t retryFilterTiler) StreamFeatures(ctx context.Context, layer string, bounds Bounder, properties map[string]string, fn FeatureConsumer) error {
+	return t.mw.do(ctx, func() error {
+		return t.filter.StreamFeatures(ctx, layer, bounds, properties, fn)
+	})
+}
+
+// do invokes call at most mw.maxAttempts times in all, doubling
+// mw.baseDelay between attempts and giving up early if ctx is done.
+func (mw retryMiddleware) do(ctx context.Context, call func() error) error {
+	var err error
+	delay := mw.baseDelay
+
+	attempt := 1
+	for ; attempt <= mw.maxAttempts; attempt++ {
+		if err = call(); err == nil {
+			return nil
+		}
+		if ctx.Err() != nil || attempt == mw.maxAttempts {
+			break
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		delay *= 2
+	}
+
+	return fmt.Errorf("retry middleware: giving up after %v attempts: %w", attempt, err)
+}
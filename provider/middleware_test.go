@@ -0,0 +1,211 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// stubTiler is a Tiler whose TileFeatures fails until it has been called
+// failUntil times, so middleware can be exercised without a real provider.
+type stubTiler struct {
+	calls     int
+	failUntil int
+}
+
+func (s *stubTiler) Layers() ([]LayerInfo, error) { return nil, nil }
+
+func (s *stubTiler) TileFeatures(ctx context.Context, layer string, t Tile, fn func(f *Feature) error) error {
+	s.calls++
+	if s.calls <= s.failUntil {
+		return errors.New("stub: upstream failure")
+	}
+	return nil
+}
+
+// stubFilterTiler is a stubTiler that also implements Filterer, for
+// asserting that middleware only advertises StreamFeatures support when
+// the wrapped Tiler actually has it.
+type stubFilterTiler struct {
+	stubTiler
+}
+
+func (s *stubFilterTiler) StreamFeatures(ctx context.Context, layer string, bounds Bounder, properties map[string]string, fn FeatureConsumer) error {
+	return nil
+}
+
+func TestMiddlewareWrapMatchesFiltererCapability(t *testing.T) {
+	middlewares := []struct {
+		name string
+		mw   Middleware
+	}{
+		{"retry", retryMiddleware{maxAttempts: 1, baseDelay: time.Millisecond}},
+		{"circuit_breaker", circuitBreakerMiddleware{threshold: 1, cooldown: time.Millisecond}},
+		{"tracing", tracingMiddleware{}},
+	}
+
+	for _, tc := range middlewares {
+		if _, ok := tc.mw.Wrap(&stubTiler{}).(Filterer); ok {
+			t.Errorf("%v: wrapping a plain Tiler must not satisfy Filterer", tc.name)
+		}
+		if _, ok := tc.mw.Wrap(&stubFilterTiler{}).(Filterer); !ok {
+			t.Errorf("%v: wrapping a Filterer must still satisfy Filterer", tc.name)
+		}
+	}
+}
+
+func TestRetryMiddlewareSucceedsAfterFailures(t *testing.T) {
+	stub := &stubTiler{failUntil: 2}
+	mw, err := newRetryMiddleware(map[string]interface{}{
+		ConfigKeyRetryMaxAttempts: 3,
+		ConfigKeyRetryBaseDelay:   time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tiler := mw.Wrap(stub)
+	if err := tiler.TileFeatures(context.Background(), "land", nil, nil); err != nil {
+		t.Fatalf("expected success within maxAttempts, got %v", err)
+	}
+	if stub.calls != 3 {
+		t.Errorf("expected 3 calls (2 failures + 1 success), got %v", stub.calls)
+	}
+}
+
+func TestRetryMiddlewareGivesUpAfterMaxAttempts(t *testing.T) {
+	stub := &stubTiler{failUntil: 100}
+	mw, err := newRetryMiddleware(map[string]interface{}{
+		ConfigKeyRetryMaxAttempts: 3,
+		ConfigKeyRetryBaseDelay:   time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tiler := mw.Wrap(stub)
+	if err := tiler.TileFeatures(context.Background(), "land", nil, nil); err == nil {
+		t.Fatal("expected error after exhausting retries, got nil")
+	}
+	if stub.calls != 3 {
+		t.Errorf("expected exactly maxAttempts (3) calls, got %v", stub.calls)
+	}
+}
+
+func TestRetryMiddlewareBaseDelayFromDurationString(t *testing.T) {
+	mw, err := newRetryMiddleware(map[string]interface{}{
+		ConfigKeyRetryBaseDelay: "200ms",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rmw := mw.(retryMiddleware)
+	if rmw.baseDelay != 200*time.Millisecond {
+		t.Errorf("expected baseDelay 200ms, got %v", rmw.baseDelay)
+	}
+}
+
+func TestRetryMiddlewareBaseDelayFromSeconds(t *testing.T) {
+	mw, err := newRetryMiddleware(map[string]interface{}{
+		ConfigKeyRetryBaseDelay: 2,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rmw := mw.(retryMiddleware)
+	if rmw.baseDelay != 2*time.Second {
+		t.Errorf("expected baseDelay 2s, got %v", rmw.baseDelay)
+	}
+}
+
+func TestCircuitBreakerOpensAfterThreshold(t *testing.T) {
+	stub := &stubTiler{failUntil: 100}
+	mw, err := newCircuitBreakerMiddleware(map[string]interface{}{
+		ConfigKeyBreakerThreshold: 2,
+		ConfigKeyBreakerCooldown:  time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tiler := mw.Wrap(stub)
+
+	for i := 0; i < 2; i++ {
+		if err := tiler.TileFeatures(context.Background(), "land", nil, nil); err == nil {
+			t.Fatal("expected upstream failure to propagate")
+		}
+	}
+	if stub.calls != 2 {
+		t.Fatalf("expected upstream to be called twice before breaker opens, got %v", stub.calls)
+	}
+
+	// Breaker should now be open and short-circuit without calling upstream.
+	if err := tiler.TileFeatures(context.Background(), "land", nil, nil); err == nil {
+		t.Fatal("expected breaker-open error, got nil")
+	}
+	if stub.calls != 2 {
+		t.Errorf("expected breaker to short-circuit without calling upstream, got %v calls", stub.calls)
+	}
+}
+
+func TestCircuitBreakerHalfOpenProbeRecovers(t *testing.T) {
+	stub := &stubTiler{failUntil: 1}
+	mw, err := newCircuitBreakerMiddleware(map[string]interface{}{
+		ConfigKeyBreakerThreshold: 1,
+		ConfigKeyBreakerCooldown:  time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tiler := mw.Wrap(stub)
+
+	if err := tiler.TileFeatures(context.Background(), "land", nil, nil); err == nil {
+		t.Fatal("expected first call to fail and open the breaker")
+	}
+
+	time.Sleep(2 * time.Millisecond)
+
+	// Cooldown elapsed; this call should probe through to upstream and succeed.
+	if err := tiler.TileFeatures(context.Background(), "land", nil, nil); err != nil {
+		t.Fatalf("expected half-open probe to succeed, got %v", err)
+	}
+	if stub.calls != 2 {
+		t.Errorf("expected probe to reach upstream, got %v calls", stub.calls)
+	}
+
+	// Breaker should be closed again; upstream stays reachable.
+	if err := tiler.TileFeatures(context.Background(), "land", nil, nil); err != nil {
+		t.Fatalf("expected breaker closed, upstream reachable again, got %v", err)
+	}
+	if stub.calls != 3 {
+		t.Errorf("expected breaker to stay closed and call upstream, got %v calls", stub.calls)
+	}
+}
+
+func TestCircuitBreakerCooldownFromDurationString(t *testing.T) {
+	mw, err := newCircuitBreakerMiddleware(map[string]interface{}{
+		ConfigKeyBreakerCooldown: "1500ms",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cbmw := mw.(circuitBreakerMiddleware)
+	if cbmw.cooldown != 1500*time.Millisecond {
+		t.Errorf("expected cooldown 1500ms, got %v", cbmw.cooldown)
+	}
+}
+
+func TestCircuitBreakerCooldownFromSeconds(t *testing.T) {
+	mw, err := newCircuitBreakerMiddleware(map[string]interface{}{
+		ConfigKeyBreakerCooldown: int64(5),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cbmw := mw.(circuitBreakerMiddleware)
+	if cbmw.cooldown != 5*time.Second {
+		t.Errorf("expected cooldown 5s, got %v", cbmw.cooldown)
+	}
+}
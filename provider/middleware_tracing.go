@@ -0,0 +1,88 @@
+package provider
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is used as the OpenTelemetry instrumentation scope for spans
+// created by the tracing middleware.
+const tracerName = "github.com/go-spatial/tegola/provider"
+
+func init() {
+	RegisterMiddleware("tracing", newTracingMiddleware)
+}
+
+// tracingMiddleware wraps TileFeatures/StreamFeatures calls in an
+// OpenTelemetry span, so a flaky or slow upstream shows up in distributed
+// traces. TileFeatures spans carry z/x/y/layer attributes; StreamFeatures
+// spans carry only the layer attribute.
+type tracingMiddleware struct {
+	tracer trace.Tracer
+}
+
+func newTracingMiddleware(config map[string]interface{}) (Middleware, error) {
+	return tracingMiddleware{tracer: otel.Tracer(tracerName)}, nil
+}
+
+// Wrap returns a tracingTiler if t doesn't implement Filterer, or a
+// tracingFilterTiler if it does, so the wrapped value's own Filterer
+// type-assertion reports the same capability the wrapped provider had
+// rather than unconditionally claiming StreamFeatures support.
+func (m tracingMiddleware) Wrap(t Tiler) Tiler {
+	tt := tracingTiler{next: t, mw: m}
+	if f, ok := t.(Filterer); ok {
+		return tracingFilterTiler{tracingTiler: tt, filter: f}
+	}
+	return tt
+}
+
+type tracingTiler struct {
+	next Tiler
+	mw   tracingMiddleware
+}
+
+func (t tracingTiler) Layers() ([]LayerInfo, error) { return t.next.Layers() }
+
+func (t tracingTiler) TileFeatures(ctx context.Context, layer string, tile Tile, fn func(f *Feature) error) error {
+	z, x, y := tile.ZXY()
+
+	ctx, span := t.mw.tracer.Start(ctx, "provider.TileFeatures",
+		trace.WithAttributes(
+			attribute.String("layer", layer),
+			attribute.Int64("z", int64(z)),
+			attribute.Int64("x", int64(x)),
+			attribute.Int64("y", int64(y)),
+		),
+	)
+	defer span.End()
+
+	err := t.next.TileFeatures(ctx, layer, tile, fn)
+	if err != nil {
+		span.RecordError(err)
+	}
+	return err
+}
+
+// tracingFilterTiler adds StreamFeatures to tracingTiler for providers
+// that implement Filterer; plain Tilers are wrapped as a tracingTiler
+// instead, so they don't satisfy a Filterer type-assertion only to fail
+// at call time.
+type tracingFilterTiler struct {
+	tracingTiler
+	filter Filterer
+}
+
+func (t tracingFilterTiler) StreamFeatures(ctx context.Context, layer string, bounds Bounder, properties map[string]string, fn FeatureConsumer) error {
+	ctx, span := t.mw.tracer.Start(ctx, "provider.StreamFeatures", trace.WithAttributes(attribute.String("layer", layer)))
+	defer span.End()
+
+	err := t.filter.StreamFeatures(ctx, layer, bounds, properties, fn)
+	if err != nil {
+		span.RecordError(err)
+	}
+	return err
+}
@@ -0,0 +1,213 @@
+package postgis
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-spatial/tegola/provider"
+)
+
+// identifierRE matches the column identifiers we're willing to splice
+// into generated SQL unquoted; anything else is rejected rather than
+// escaped, since properties is attacker-controllable (tile filter
+// params from a request).
+var identifierRE = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// Config keys for the time/index columns a layer's Filterer bounds apply
+// to. When unset, time/index bounds never narrow the query, matching the
+// documented "features without the column pass all bounds" semantics.
+const (
+	ConfigKeyTimeField  = "time_fieldname"
+	ConfigKeyIndexField = "index_fieldname"
+)
+
+var _ provider.Filterer = Provider{}
+
+// StreamFeatures implements provider.Filterer by translating bounds and
+// properties into a SQL WHERE clause appended to the layer's configured
+// SQL, rather than filtering client-side.
+func (p Provider) StreamFeatures(ctx context.Context, layer string, bounds provider.Bounder, properties map[string]string, fn provider.FeatureConsumer) error {
+	l, ok := p.layers[layer]
+	if !ok {
+		return fmt.Errorf("postgis: layer %v not registered", layer)
+	}
+
+	sql := replaceTokens(l.sql, tokenReplacements{
+		bbox:       "ST_MakeEnvelope(-20037508.34,-20037508.34,20037508.34,20037508.34," + strconv.FormatUint(l.srid, 10) + ")",
+		zoom:       "0",
+		timeStart:  boundsTimeToken(bounds, true),
+		timeEnd:    boundsTimeToken(bounds, false),
+		indexStart: boundsIndexToken(bounds, true),
+		indexEnd:   boundsIndexToken(bounds, false),
+	})
+
+	where, args, err := filterWhereClause(l, bounds, properties)
+	if err != nil {
+		return fmt.Errorf("postgis: error building filter for layer %v: %w", layer, err)
+	}
+
+	wrapped := fmt.Sprintf("SELECT q.* FROM (%v) AS q", sql)
+	if where != "" {
+		wrapped += " WHERE " + where
+	}
+
+	rows, err := p.pool.QueryEx(ctx, wrapped, nil, args...)
+	if err != nil {
+		return fmt.Errorf("postgis: error running layer %v filter query: %v", layer, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		vals, err := rows.Values()
+		if err != nil {
+			return err
+		}
+
+		gid, g, tags, err := decipherFields(ctx, l.geomFieldname, l.idFieldname, rows.FieldDescriptions(), vals)
+		if err != nil {
+			return fmt.Errorf("postgis: error decoding row for layer %v: %v", layer, err)
+		}
+
+		iTags := make(map[string]interface{}, len(tags))
+		for k, v := range tags {
+			iTags[k] = v
+		}
+
+		f := &provider.Feature{ID: gid, Geometry: g, SRID: l.srid, Tags: iTags}
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// filterWhereClause builds the predicate appended to the layer's wrapped
+// SQL for the given bounds and properties, returning the parameterized
+// clause alongside the bind values referenced by its $n placeholders.
+// Bounds dimensions the layer has no configured column for are skipped
+// (features without that data pass all bounds, per provider.Filterer's
+// documented semantics), as are nil Bounder fields.
+func filterWhereClause(l Layer, bounds provider.Bounder, properties map[string]string) (string, []interface{}, error) {
+	var clauses []string
+	var args []interface{}
+
+	timeField, _ := layerConfigString(l, ConfigKeyTimeField)
+	if timeField != "" && bounds != nil {
+		if te := bounds.TimeExtent(); te != nil {
+			if start := (*te).StartTime(); start != nil {
+				args = append(args, start.UTC())
+				clauses = append(clauses, fmt.Sprintf("(%v IS NULL OR %v >= $%v)", timeField, timeField, len(args)))
+			}
+			if end := (*te).EndTime(); end != nil {
+				args = append(args, end.UTC())
+				clauses = append(clauses, fmt.Sprintf("(%v IS NULL OR %v <= $%v)", timeField, timeField, len(args)))
+			}
+		}
+	}
+
+	if bounds != nil {
+		if ge := bounds.GeomExtent(); ge != nil {
+			clauses = append(clauses, fmt.Sprintf(
+				"(%v IS NULL OR %v && ST_MakeEnvelope(%v,%v,%v,%v,%v))",
+				l.geomFieldname, l.geomFieldname, ge.MinX(), ge.MinY(), ge.MaxX(), ge.MaxY(), l.srid,
+			))
+		}
+	}
+
+	indexField, _ := layerConfigString(l, ConfigKeyIndexField)
+	if indexField != "" && bounds != nil {
+		if ie := bounds.IndexExtent(); ie != nil {
+			args = append(args, (*ie).StartIndex(), (*ie).EndIndex())
+			clauses = append(clauses, fmt.Sprintf(
+				"(%v IS NULL OR (%v >= $%v AND %v <= $%v))",
+				indexField, indexField, len(args)-1, indexField, len(args),
+			))
+		}
+	}
+
+	for k, v := range properties {
+		if !identifierRE.MatchString(k) {
+			return "", nil, fmt.Errorf("invalid property name %q", k)
+		}
+		args = append(args, convertPropertyValue(v))
+		clauses = append(clauses, fmt.Sprintf("(%v IS NULL OR %v = $%v)", k, k, len(args)))
+	}
+
+	return strings.Join(clauses, " AND "), args, nil
+}
+
+// layerConfigString returns the Layer's configured time or index column
+// name for key, and whether one is set; any other key returns "", false.
+func layerConfigString(l Layer, key string) (string, bool) {
+	switch key {
+	case ConfigKeyTimeField:
+		return l.timeFieldname, l.timeFieldname != ""
+	case ConfigKeyIndexField:
+		return l.indexFieldname, l.indexFieldname != ""
+	}
+	return "", false
+}
+
+// convertPropertyValue fuzzily converts a string property value to its
+// native Go/pgtype equivalent (int, float, bool, timestamp) so it's bound
+// as a typed parameter rather than spliced into the SQL text; a value
+// that doesn't parse as any of those is bound as-is to compare against a
+// text column.
+func convertPropertyValue(v string) interface{} {
+	if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(v, 64); err == nil {
+		return f
+	}
+	if b, err := strconv.ParseBool(v); err == nil {
+		return b
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t
+	}
+	return v
+}
+
+func boundsTimeToken(bounds provider.Bounder, start bool) string {
+	if bounds == nil {
+		return "NULL"
+	}
+	te := bounds.TimeExtent()
+	if te == nil {
+		return "NULL"
+	}
+	var t *time.Time
+	if start {
+		t = (*te).StartTime()
+	} else {
+		t = (*te).EndTime()
+	}
+	if t == nil {
+		return "NULL"
+	}
+	return "'" + t.UTC().Format(time.RFC3339) + "'"
+}
+
+func boundsIndexToken(bounds provider.Bounder, start bool) string {
+	if bounds == nil {
+		return "NULL"
+	}
+	ie := bounds.IndexExtent()
+	if ie == nil {
+		return "NULL"
+	}
+	if start {
+		return strconv.FormatUint(uint64((*ie).StartIndex()), 10)
+	}
+	return strconv.FormatUint(uint64((*ie).EndIndex()), 10)
+}
@@ -0,0 +1,148 @@
+package postgis
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-spatial/geom"
+	"github.com/go-spatial/tegola/provider"
+)
+
+type fakeTimeExtent struct{ start, end *time.Time }
+
+func (f fakeTimeExtent) StartTime() *time.Time { return f.start }
+func (f fakeTimeExtent) EndTime() *time.Time   { return f.end }
+
+type fakeIndexExtent struct{ start, end uint }
+
+func (f fakeIndexExtent) StartIndex() uint { return f.start }
+func (f fakeIndexExtent) EndIndex() uint   { return f.end }
+
+type fakeBounds struct {
+	te *provider.TimeExtent
+	ge *geom.Extent
+	ie *provider.IndexExtent
+}
+
+func (f fakeBounds) TimeExtent() *provider.TimeExtent   { return f.te }
+func (f fakeBounds) GeomExtent() *geom.Extent           { return f.ge }
+func (f fakeBounds) IndexExtent() *provider.IndexExtent { return f.ie }
+
+func TestConvertPropertyValue(t *testing.T) {
+	testcases := []struct {
+		in       string
+		expected interface{}
+	}{
+		{"42", int64(42)},
+		{"3.14", 3.14},
+		{"true", true},
+		{"false", false},
+		{"hello", "hello"},
+	}
+
+	for i, tc := range testcases {
+		got := convertPropertyValue(tc.in)
+		if !reflect.DeepEqual(tc.expected, got) {
+			t.Errorf("[%v] convertPropertyValue(%q) = %#v (%T), want %#v (%T)", i, tc.in, got, got, tc.expected, tc.expected)
+		}
+	}
+}
+
+func TestFilterWhereClauseRejectsInvalidPropertyName(t *testing.T) {
+	l := Layer{name: "land", geomFieldname: "geom", srid: 3857}
+
+	testcases := []string{
+		"height; DROP TABLE land;--",
+		"height = 1 OR 1=1",
+		"\"height\"",
+		"height'",
+	}
+
+	for _, name := range testcases {
+		_, _, err := filterWhereClause(l, nil, map[string]string{name: "1"})
+		if err == nil {
+			t.Errorf("expected error for invalid property name %q, got nil", name)
+		}
+	}
+}
+
+func TestFilterWhereClauseParameterizesProperties(t *testing.T) {
+	l := Layer{name: "land", geomFieldname: "geom", srid: 3857}
+
+	where, args, err := filterWhereClause(l, nil, map[string]string{"height": "9"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if where != "(height IS NULL OR height = $1)" {
+		t.Errorf("unexpected where clause: %v", where)
+	}
+	if len(args) != 1 || args[0] != int64(9) {
+		t.Errorf("unexpected args: %#v", args)
+	}
+}
+
+func TestFilterWhereClauseNoFilters(t *testing.T) {
+	l := Layer{name: "land", geomFieldname: "geom", srid: 3857}
+
+	where, args, err := filterWhereClause(l, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if where != "" || len(args) != 0 {
+		t.Errorf("expected empty clause and no args, got %q, %#v", where, args)
+	}
+}
+
+func TestFilterWhereClauseTimeBoundAllowsNullColumn(t *testing.T) {
+	l := Layer{name: "land", geomFieldname: "geom", srid: 3857, timeFieldname: "ts"}
+
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	var te provider.TimeExtent = fakeTimeExtent{start: &start}
+	bounds := fakeBounds{te: &te}
+
+	where, args, err := filterWhereClause(l, bounds, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if where != "(ts IS NULL OR ts >= $1)" {
+		t.Errorf("unexpected where clause: %v", where)
+	}
+	if len(args) != 1 {
+		t.Errorf("unexpected args: %#v", args)
+	}
+}
+
+func TestFilterWhereClauseIndexBoundAllowsNullColumn(t *testing.T) {
+	l := Layer{name: "land", geomFieldname: "geom", srid: 3857, indexFieldname: "idx"}
+
+	var ie provider.IndexExtent = fakeIndexExtent{start: 0, end: 10}
+	bounds := fakeBounds{ie: &ie}
+
+	where, args, err := filterWhereClause(l, bounds, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if where != "(idx IS NULL OR (idx >= $1 AND idx <= $2))" {
+		t.Errorf("unexpected where clause: %v", where)
+	}
+	if len(args) != 2 {
+		t.Errorf("unexpected args: %#v", args)
+	}
+}
+
+func TestReplaceTokens(t *testing.T) {
+	sql := "SELECT gid FROM t WHERE geom && !BBOX! AND gid = !ZOOM! AND ts >= !TIME_START! AND idx <= !INDEX_END!"
+
+	got := replaceTokens(sql, tokenReplacements{
+		bbox:      "ST_MakeEnvelope(0,0,1,1,3857)",
+		zoom:      "4",
+		timeStart: "'2020-01-01T00:00:00Z'",
+	})
+
+	want := "SELECT gid FROM t WHERE geom && ST_MakeEnvelope(0,0,1,1,3857) AND gid = 4 AND ts >= '2020-01-01T00:00:00Z' AND idx <= NULL"
+	if got != want {
+		t.Errorf("replaceTokens() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,365 @@
+// Package postgis implements the tegola provider.Tiler and
+// provider.Filterer interfaces against a PostGIS-enabled Postgres
+// database, rendering layers from user-supplied SQL.
+package postgis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx"
+
+	"github.com/go-spatial/geom"
+	"github.com/go-spatial/tegola/internal/log"
+	"github.com/go-spatial/tegola/provider"
+)
+
+// Config keys for the postgis provider.
+const (
+	ConfigKeyHost      = "host"
+	ConfigKeyPort      = "port"
+	ConfigKeyDB        = "database"
+	ConfigKeyUser      = "user"
+	ConfigKeyPassword  = "password"
+	ConfigKeyMaxConn   = "max_connections"
+	ConfigKeySRID      = "srid"
+	ConfigKeyLayers    = "layers"
+	ConfigKeyLayerName = "name"
+	ConfigKeySQL       = "sql"
+	ConfigKeyGeomField = "geometry_fieldname"
+	ConfigKeyIDField   = "id_fieldname"
+)
+
+// Name is the name the provider is registered under.
+const Name = "postgis"
+
+// DefaultSRID is the SRID tegola's pipeline works in.
+const DefaultSRID = 3857
+
+func init() {
+	provider.Register(Name, NewTileProvider, nil)
+}
+
+// Layer holds the configuration and cached metadata for a single
+// configured layer.
+type Layer struct {
+	name           string
+	sql            string
+	geomFieldname  string
+	idFieldname    string
+	timeFieldname  string
+	indexFieldname string
+	geomType       geom.Geometry
+	srid           uint64
+}
+
+func (l Layer) Name() string            { return l.name }
+func (l Layer) GeomType() geom.Geometry { return l.geomType }
+func (l Layer) SRID() uint64            { return l.srid }
+
+// ModificationTag is not supported by the postgis provider; user SQL can
+// reference arbitrary tables so there's no single cheap signal to hash.
+func (l Layer) ModificationTag() *string { return nil }
+
+// Provider is a Tiler (and Filterer) backed by a PostGIS database.
+type Provider struct {
+	pool   *pgx.ConnPool
+	srid   uint64
+	layers map[string]Layer
+}
+
+// NewTileProvider instantiates and returns a new postgis provider, opening
+// a connection pool to the configured database and inspecting each
+// configured layer's geometry type.
+func NewTileProvider(config map[string]interface{}) (provider.Tiler, error) {
+	host, _ := config[ConfigKeyHost].(string)
+	db, _ := config[ConfigKeyDB].(string)
+	user, _ := config[ConfigKeyUser].(string)
+	password, _ := config[ConfigKeyPassword].(string)
+
+	var port int64
+	switch v := config[ConfigKeyPort].(type) {
+	case int64:
+		port = v
+	case int:
+		port = int64(v)
+	}
+
+	srid := uint64(DefaultSRID)
+	switch v := config[ConfigKeySRID].(type) {
+	case uint64:
+		srid = v
+	case int64:
+		srid = uint64(v)
+	case int:
+		srid = uint64(v)
+	case float64:
+		srid = uint64(v)
+	}
+
+	connConfig := pgx.ConnConfig{
+		Host:     host,
+		Port:     uint16(port),
+		Database: db,
+		User:     user,
+		Password: password,
+	}
+
+	pool, err := pgx.NewConnPool(pgx.ConnPoolConfig{ConnConfig: connConfig, MaxConnections: 5})
+	if err != nil {
+		return nil, fmt.Errorf("postgis: unable to create connection pool: %v", err)
+	}
+
+	p := Provider{
+		pool:   pool,
+		srid:   srid,
+		layers: map[string]Layer{},
+	}
+
+	layerConfigs, _ := config[ConfigKeyLayers].([]map[string]interface{})
+	for _, lc := range layerConfigs {
+		name, ok := lc[ConfigKeyLayerName].(string)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("postgis: layer missing %v", ConfigKeyLayerName)
+		}
+		sql, ok := lc[ConfigKeySQL].(string)
+		if !ok || sql == "" {
+			return nil, fmt.Errorf("postgis: layer %v missing %v", name, ConfigKeySQL)
+		}
+
+		geomFieldname, _ := lc[ConfigKeyGeomField].(string)
+		if geomFieldname == "" {
+			geomFieldname = "geom"
+		}
+		idFieldname, _ := lc[ConfigKeyIDField].(string)
+		if idFieldname == "" {
+			idFieldname = "gid"
+		}
+
+		timeFieldname, _ := lc[ConfigKeyTimeField].(string)
+		indexFieldname, _ := lc[ConfigKeyIndexField].(string)
+
+		layer := Layer{
+			name:           name,
+			sql:            sql,
+			geomFieldname:  geomFieldname,
+			idFieldname:    idFieldname,
+			timeFieldname:  timeFieldname,
+			indexFieldname: indexFieldname,
+			srid:           srid,
+		}
+
+		if err := p.layerGeomType(&layer); err != nil {
+			return nil, fmt.Errorf("postgis: unable to determine geometry type for layer %v: %v", name, err)
+		}
+
+		p.layers[name] = layer
+	}
+
+	return p, nil
+}
+
+// layerGeomType runs l's SQL bounded to the full web-mercator extent and
+// LIMIT 1 to inspect the shape of the geometry column returned, caching
+// the result on l.geomType.
+func (p Provider) layerGeomType(l *Layer) error {
+	sql := replaceTokens(l.sql, tokenReplacements{
+		bbox: "ST_MakeEnvelope(-20037508.34,-20037508.34,20037508.34,20037508.34," + strconv.FormatUint(l.srid, 10) + ")",
+		zoom: "0",
+	})
+	sql = fmt.Sprintf("SELECT q.* FROM (%v) AS q LIMIT 1", sql)
+
+	rows, err := p.pool.Query(sql)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return fmt.Errorf("layer %v: query for geometry-type detection returned no rows", l.name)
+	}
+
+	vals, err := rows.Values()
+	if err != nil {
+		return err
+	}
+
+	for i, fd := range rows.FieldDescriptions() {
+		if fd.Name != l.geomFieldname {
+			continue
+		}
+		wkb, ok := vals[i].([]byte)
+		if !ok {
+			return fmt.Errorf("geometry field %v did not decode to bytes", l.geomFieldname)
+		}
+		g, err := decodeWKB(wkb)
+		if err != nil {
+			return err
+		}
+		l.geomType = g
+		return nil
+	}
+
+	return fmt.Errorf("geometry field %v not found in query results", l.geomFieldname)
+}
+
+// Layers returns the layers configured on this provider.
+func (p Provider) Layers() ([]provider.LayerInfo, error) {
+	infos := make([]provider.LayerInfo, 0, len(p.layers))
+	for _, l := range p.layers {
+		infos = append(infos, l)
+	}
+	return infos, nil
+}
+
+// TileFeatures streams the decoded features for layer within t's buffered
+// extent, substituting !BBOX! and !ZOOM! into the layer's configured SQL.
+func (p Provider) TileFeatures(ctx context.Context, layer string, t provider.Tile, fn func(f *provider.Feature) error) error {
+	l, ok := p.layers[layer]
+	if !ok {
+		return fmt.Errorf("postgis: layer %v not registered", layer)
+	}
+
+	z, _, _ := t.ZXY()
+	extent, srid := t.BufferedExtent()
+
+	sql := replaceTokens(l.sql, tokenReplacements{
+		bbox: bboxToken(extent, srid),
+		zoom: strconv.FormatUint(uint64(z), 10),
+	})
+
+	rows, err := p.pool.QueryEx(ctx, sql, nil)
+	if err != nil {
+		return fmt.Errorf("postgis: error running layer %v query: %v", layer, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		vals, err := rows.Values()
+		if err != nil {
+			return err
+		}
+
+		gid, g, tags, err := decipherFields(ctx, l.geomFieldname, l.idFieldname, rows.FieldDescriptions(), vals)
+		if err != nil {
+			return fmt.Errorf("postgis: error decoding row for layer %v: %v", layer, err)
+		}
+
+		iTags := make(map[string]interface{}, len(tags))
+		for k, v := range tags {
+			iTags[k] = v
+		}
+
+		f := &provider.Feature{
+			ID:       gid,
+			Geometry: g,
+			SRID:     l.srid,
+			Tags:     iTags,
+		}
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// decipherFields splits a row's values into the feature id, geometry, and
+// remaining columns (as string-valued tags), skipping the geometry and id
+// fields themselves.
+func decipherFields(ctx context.Context, geomFieldname, idFieldname string, descriptions []pgx.FieldDescription, values []interface{}) (gid uint64, g geom.Geometry, tags map[string]string, err error) {
+	tags = map[string]string{}
+
+	for i, fd := range descriptions {
+		switch fd.Name {
+		case idFieldname:
+			gid, err = toUint64(values[i])
+			if err != nil {
+				return 0, nil, nil, fmt.Errorf("unable to convert id field %v: %v", idFieldname, err)
+			}
+		case geomFieldname:
+			wkb, ok := values[i].([]byte)
+			if !ok {
+				return 0, nil, nil, fmt.Errorf("geometry field %v did not decode to bytes", geomFieldname)
+			}
+			g, err = decodeWKB(wkb)
+			if err != nil {
+				return 0, nil, nil, err
+			}
+		default:
+			if values[i] == nil {
+				continue
+			}
+			tags[fd.Name] = fmt.Sprintf("%v", values[i])
+		}
+	}
+
+	return gid, g, tags, nil
+}
+
+func toUint64(v interface{}) (uint64, error) {
+	switch t := v.(type) {
+	case int64:
+		return uint64(t), nil
+	case int32:
+		return uint64(t), nil
+	case uint64:
+		return t, nil
+	default:
+		return 0, fmt.Errorf("unsupported id type %T", v)
+	}
+}
+
+func bboxToken(extent *geom.Extent, srid uint64) string {
+	return fmt.Sprintf("ST_MakeEnvelope(%v,%v,%v,%v,%v)", extent.MinX(), extent.MinY(), extent.MaxX(), extent.MaxY(), srid)
+}
+
+type tokenReplacements struct {
+	bbox       string
+	zoom       string
+	timeStart  string
+	timeEnd    string
+	indexStart string
+	indexEnd   string
+}
+
+// replaceTokens substitutes the !BBOX!/!ZOOM! tokens tegola documents for
+// layer SQL, along with the !TIME_START!/!TIME_END!/!INDEX_START!/
+// !INDEX_END! tokens a Filterer query may additionally reference. Tokens
+// left at their zero value substitute to SQL NULL so they're inert unless
+// a request actually supplied that bound.
+func replaceTokens(sql string, r tokenReplacements) string {
+	replacements := []struct{ token, value string }{
+		{"!BBOX!", r.bbox},
+		{"!ZOOM!", r.zoom},
+		{"!TIME_START!", orNull(r.timeStart)},
+		{"!TIME_END!", orNull(r.timeEnd)},
+		{"!INDEX_START!", orNull(r.indexStart)},
+		{"!INDEX_END!", orNull(r.indexEnd)},
+	}
+	for _, rep := range replacements {
+		sql = strings.Replace(sql, rep.token, rep.value, -1)
+	}
+	return sql
+}
+
+func orNull(v string) string {
+	if v == "" {
+		return "NULL"
+	}
+	return v
+}
+
+func decodeWKB(b []byte) (geom.Geometry, error) {
+	log.Debugf("postgis: decoding %v byte WKB geometry", len(b))
+	return geom.DecodeWKB(b)
+}
@@ -131,7 +131,12 @@ func For(name string, config map[string]interface{}) (Tiler, error) {
 		return nil, fmt.Errorf("No providers registered by the name: %v, known providers(%v)", name, strings.Join(Drivers(), ","))
 	}
 
-	return p.init(config)
+	t, err := p.init(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return wrapMiddleware(name, t, config)
 }
 
 func Cleanup() {